@@ -14,15 +14,33 @@
 package os
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	_os "os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/gohugoio/hugo/deps"
 	"github.com/spf13/afero"
 	"github.com/spf13/cast"
 )
 
+// defaultMaxScanTokenSize is the default maximum buffer size used by
+// bufio.Scanner when reading a file line by line. It is well above bufio's
+// default of 64KB so that lines in generated JSON or CSV files are not
+// truncated. It can be overridden by the maxScanTokenSize site config.
+const defaultMaxScanTokenSize = 10 * 1024 * 1024
+
+// defaultGetenvAllowlist is used when the site config does not define
+// security.funcs.getenv. It only allows variables whose names start with
+// HUGO_, keeping the rest of the process environment, e.g. secrets, out of reach
+// of templates by default.
+var defaultGetenvAllowlist = []string{`^HUGO_`}
+
 // New returns a new instance of the os-namespaced template functions.
 func New(deps *deps.Deps) *Namespace {
 
@@ -38,9 +56,38 @@ func New(deps *deps.Deps) *Namespace {
 		}
 	}
 
+	patterns := defaultGetenvAllowlist
+	if deps.Cfg != nil {
+		if configured := deps.Cfg.GetStringSlice("security.funcs.getenv"); len(configured) > 0 {
+			patterns = configured
+		}
+	}
+
+	var allow []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			if deps.Log != nil {
+				deps.Log.Warnf("invalid security.funcs.getenv pattern %q: %s", pattern, err)
+			}
+			continue
+		}
+		allow = append(allow, re)
+	}
+
+	maxScanTokenSize := defaultMaxScanTokenSize
+	if deps.Cfg != nil {
+		if v := deps.Cfg.GetInt("maxScanTokenSize"); v > 0 {
+			maxScanTokenSize = v
+		}
+	}
+
 	return &Namespace{
-		readFileFs: rfs,
-		deps:       deps,
+		readFileFs:       rfs,
+		deps:             deps,
+		getenvAllow:      allow,
+		loadedEnv:        make(map[string]string),
+		maxScanTokenSize: maxScanTokenSize,
 	}
 }
 
@@ -48,19 +95,103 @@ func New(deps *deps.Deps) *Namespace {
 type Namespace struct {
 	readFileFs afero.Fs
 	deps       *deps.Deps
+
+	// getenvAllow holds the compiled security.funcs.getenv patterns. A key
+	// passed to Getenv that was not set via LoadEnv must match at least one
+	// of these to be returned.
+	getenvAllow []*regexp.Regexp
+
+	// loadedEnv holds the variables merged in by LoadEnv, consulted by
+	// Getenv ahead of the real process environment.
+	loadedEnvMu sync.RWMutex
+	loadedEnv   map[string]string
+
+	// maxScanTokenSize is the per-line buffer size used by scanFileLines,
+	// configurable via the maxScanTokenSize site config.
+	maxScanTokenSize int
 }
 
 // Getenv retrieves the value of the environment variable named by the key.
-// It returns the value, which will be empty if the variable is not present.
+// Variables merged in via LoadEnv are consulted first and are exempt from
+// the security.funcs.getenv allow-list, since loading them was itself an
+// explicit, author-controlled action. Failing that, it returns the value of
+// the real process environment variable, which will be empty if the
+// variable is not present or if the key is not allowed by the site's
+// security.funcs.getenv config.
 func (ns *Namespace) Getenv(key interface{}) (string, error) {
 	skey, err := cast.ToStringE(key)
 	if err != nil {
 		return "", nil
 	}
 
+	ns.loadedEnvMu.RLock()
+	v, ok := ns.loadedEnv[skey]
+	ns.loadedEnvMu.RUnlock()
+	if ok {
+		return v, nil
+	}
+
+	if !ns.getenvAllowed(skey) {
+		if ns.deps != nil && ns.deps.Log != nil {
+			ns.deps.Log.Warnf("getenv: %q is not allowed by security.funcs.getenv; returning an empty string", skey)
+		}
+		return "", nil
+	}
+
 	return _os.Getenv(skey), nil
 }
 
+// getenvAllowed reports whether key may be returned by Getenv.
+func (ns *Namespace) getenvAllowed(key string) bool {
+	for _, re := range ns.getenvAllow {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadEnv reads the dotenv-style file named by filename relative to the
+// configured WorkingDir and merges its key/value pairs into an in-memory
+// map that Getenv consults ahead of the real process environment. Lines
+// that are empty, start with `#`, or do not contain an `=` are ignored.
+func (ns *Namespace) LoadEnv(i interface{}) error {
+	filename, err := cast.ToStringE(i)
+	if err != nil {
+		return err
+	}
+
+	env := make(map[string]string)
+	if err := ns.scanFileLines(filename, func(line string) bool {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			return true
+		}
+
+		k, v, found := strings.Cut(line, "=")
+		if !found {
+			return true
+		}
+
+		k = strings.TrimSpace(k)
+		v = strings.TrimSpace(v)
+		v = strings.Trim(v, `"'`)
+		env[k] = v
+
+		return true
+	}); err != nil {
+		return fmt.Errorf("failed to load env file %q: %w", filename, err)
+	}
+
+	ns.loadedEnvMu.Lock()
+	for k, v := range env {
+		ns.loadedEnv[k] = v
+	}
+	ns.loadedEnvMu.Unlock()
+
+	return nil
+}
+
 // readFile reads the file named by filename in the given filesystem
 // and returns the contents as a string.
 // There is a upper size limit set at 1 megabytes.
@@ -97,6 +228,205 @@ func (ns *Namespace) ReadFile(i interface{}) (string, error) {
 	return readFile(ns.readFileFs, s)
 }
 
+// scanFileLines opens filename in ns.readFileFs and calls handle for every
+// line, stopping early if handle returns false. The per-line buffer is
+// capped at ns.maxScanTokenSize.
+func (ns *Namespace) scanFileLines(filename string, handle func(line string) bool) error {
+	if filename == "" {
+		return errors.New("needs a filename")
+	}
+
+	f, err := ns.readFileFs.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), ns.maxScanTokenSize)
+
+	for scanner.Scan() {
+		if !handle(scanner.Text()) {
+			break
+		}
+	}
+
+	return scanner.Err()
+}
+
+// ReadFileLines reads the file named by filename relative to the configured
+// WorkingDir and returns its contents as a slice of lines. Unlike ReadFile,
+// the file is streamed with a bufio.Scanner, so there is no 1 megabyte limit
+// on the overall file size, only on the length of any single line.
+func (ns *Namespace) ReadFileLines(i interface{}) ([]string, error) {
+	filename, err := cast.ToStringE(i)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	if err := ns.scanFileLines(filename, func(line string) bool {
+		lines = append(lines, line)
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// ReadFileHead reads up to n lines from the start of the file named by
+// filename relative to the configured WorkingDir. The file is streamed, so
+// there is no 1 megabyte limit on the overall file size.
+func (ns *Namespace) ReadFileHead(i, n interface{}) ([]string, error) {
+	filename, err := cast.ToStringE(i)
+	if err != nil {
+		return nil, err
+	}
+
+	in, err := cast.ToIntE(n)
+	if err != nil {
+		return nil, err
+	}
+	if in < 0 {
+		return nil, fmt.Errorf("n must be >= 0, got %d", in)
+	}
+	if in == 0 {
+		return nil, nil
+	}
+
+	lines := make([]string, 0, in)
+	if err := ns.scanFileLines(filename, func(line string) bool {
+		if len(lines) >= in {
+			return false
+		}
+		lines = append(lines, line)
+		return len(lines) < in
+	}); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// ReadFileTail reads up to n lines from the end of the file named by
+// filename relative to the configured WorkingDir. The file is streamed, so
+// there is no 1 megabyte limit on the overall file size.
+func (ns *Namespace) ReadFileTail(i, n interface{}) ([]string, error) {
+	filename, err := cast.ToStringE(i)
+	if err != nil {
+		return nil, err
+	}
+
+	in, err := cast.ToIntE(n)
+	if err != nil {
+		return nil, err
+	}
+	if in < 0 {
+		return nil, fmt.Errorf("n must be >= 0, got %d", in)
+	}
+
+	var lines []string
+	if err := ns.scanFileLines(filename, func(line string) bool {
+		lines = append(lines, line)
+		if len(lines) > in {
+			lines = lines[len(lines)-in:]
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// FileSize returns the size in bytes of the file named by filename relative
+// to the configured WorkingDir.
+func (ns *Namespace) FileSize(i interface{}) (int64, error) {
+	filename, err := cast.ToStringE(i)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := ns.readFileFs.Stat(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+// FileInfo holds the metadata returned by Stat.
+type FileInfo struct {
+	// Name is the base name of the file.
+	Name string
+
+	// Size is the length in bytes for regular files.
+	Size int64
+
+	// Mode is the file mode bits.
+	Mode _os.FileMode
+
+	// ModTime is the modification time.
+	ModTime time.Time
+
+	// IsDir reports whether the file is a directory.
+	IsDir bool
+
+	// AbsolutePath is the resolved absolute path of the file on disk, i.e.
+	// relative to deps.Fs.WorkingDir rather than ns.readFileFs (which may be
+	// a composite of the work dir and a content mount). If it cannot be
+	// resolved to a real, on-disk path, this falls back to the relative
+	// path that was passed in.
+	AbsolutePath string
+}
+
+// resolveAbsolutePath resolves filename, relative to ns.readFileFs, to a
+// real absolute path on disk. It tries the working-dir filesystem first,
+// since that is the one most callers mean by "the file on disk", and falls
+// back to the relative path unchanged if no BasePathFs backs either
+// filesystem (e.g. when running against an in-memory fs in tests).
+func (ns *Namespace) resolveAbsolutePath(filename string) string {
+	if ns.deps != nil && ns.deps.Fs != nil {
+		if bfs, ok := ns.deps.Fs.WorkingDir.(*afero.BasePathFs); ok {
+			if real, err := bfs.RealPath(filename); err == nil {
+				return real
+			}
+		}
+	}
+
+	if bfs, ok := ns.readFileFs.(*afero.BasePathFs); ok {
+		if real, err := bfs.RealPath(filename); err == nil {
+			return real
+		}
+	}
+
+	return filename
+}
+
+// Stat returns the os.FileInfo-like metadata for the file named by filename
+// relative to the configured WorkingDir.
+func (ns *Namespace) Stat(i interface{}) (FileInfo, error) {
+	filename, err := cast.ToStringE(i)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	info, err := ns.readFileFs.Stat(filename)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	return FileInfo{
+		Name:         info.Name(),
+		Size:         info.Size(),
+		Mode:         info.Mode(),
+		ModTime:      info.ModTime(),
+		IsDir:        info.IsDir(),
+		AbsolutePath: ns.resolveAbsolutePath(filename),
+	}, nil
+}
+
 // ReadDir lists the directory contents relative to the configured WorkingDir.
 func (ns *Namespace) ReadDir(i interface{}) ([]_os.FileInfo, error) {
 	path, err := cast.ToStringE(i)
@@ -112,6 +442,36 @@ func (ns *Namespace) ReadDir(i interface{}) ([]_os.FileInfo, error) {
 	return list, nil
 }
 
+// Glob returns the os.FileInfo of every path in ns.readFileFs matching pattern,
+// which may contain doublestar-style wildcards (`**`, `?`, `[...]` and
+// brace `{a,b}` alternatives).
+func (ns *Namespace) Glob(pattern interface{}) ([]_os.FileInfo, error) {
+	spattern, err := cast.ToStringE(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if spattern == "" {
+		return nil, errors.New("glob needs a pattern")
+	}
+
+	names, err := doublestar.Glob(afero.NewIOFS(ns.readFileFs), spattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob %q: %w", spattern, err)
+	}
+
+	infos := make([]_os.FileInfo, 0, len(names))
+	for _, name := range names {
+		info, err := ns.readFileFs.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
 // FileExists checks whether a file exists under the given path.
 func (ns *Namespace) FileExists(i interface{}) (bool, error) {
 	path, err := cast.ToStringE(i)